@@ -0,0 +1,58 @@
+// Command configcheckpoint-controller watches ConfigCheckpoint resources and
+// tags the config history repository's HEAD when one is created; see
+// pkg/storage's Checkpoint for the CLI-facing use case this serves.
+package main
+
+import (
+	"flag"
+	"time"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog"
+
+	"github.com/damemi/config-history-operator/pkg/controller/configcheckpoint"
+	"github.com/damemi/config-history-operator/pkg/storage"
+)
+
+func main() {
+	var (
+		kubeconfig  string
+		historyPath string
+		resync      time.Duration
+	)
+	flag.StringVar(&kubeconfig, "kubeconfig", "", "path to a kubeconfig; uses the in-cluster config when empty")
+	flag.StringVar(&historyPath, "history-path", "/var/lib/config-history", "path to the git-backed config history repository to tag")
+	flag.DurationVar(&resync, "resync-period", 10*time.Minute, "how often the ConfigCheckpoint informer resyncs")
+	flag.Parse()
+
+	cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		klog.Fatalf("Unable to build client config: %v", err)
+	}
+	client, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("Unable to build dynamic client: %v", err)
+	}
+
+	backend, err := storage.NewGitStorage(historyPath, nil, nil)
+	if err != nil {
+		klog.Fatalf("Unable to open config history repository at %q: %v", historyPath, err)
+	}
+	checkpointer, ok := backend.(configcheckpoint.Checkpointer)
+	if !ok {
+		klog.Fatalf("Storage backend %T does not support checkpoints", backend)
+	}
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, resync)
+	informer := factory.ForResource(configcheckpoint.Resource).Informer()
+	informer.AddEventHandler(configcheckpoint.NewController(checkpointer, client))
+
+	stop := make(chan struct{})
+	factory.Start(stop)
+	factory.WaitForCacheSync(stop)
+
+	klog.Infof("Watching ConfigCheckpoint resources, tagging %s", historyPath)
+	<-stop
+}
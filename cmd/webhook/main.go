@@ -0,0 +1,31 @@
+// Command webhook runs the mutating admission webhook that stamps watched
+// config resources with the identity of whoever changed them.
+package main
+
+import (
+	"flag"
+	"net/http"
+
+	"k8s.io/klog"
+
+	"github.com/damemi/config-history-operator/pkg/admission"
+)
+
+func main() {
+	var (
+		listenAddr string
+		certFile   string
+		keyFile    string
+	)
+	flag.StringVar(&listenAddr, "listen-address", ":8443", "address the webhook listens on")
+	flag.StringVar(&certFile, "tls-cert-file", "/etc/config-history-webhook/tls/tls.crt", "path to the webhook's TLS certificate, provisioned by the cluster's service CA")
+	flag.StringVar(&keyFile, "tls-private-key-file", "/etc/config-history-webhook/tls/tls.key", "path to the webhook's TLS private key")
+	flag.Parse()
+
+	http.Handle("/mutate", admission.NewHandler())
+
+	klog.Infof("Serving config-history mutating webhook on %s", listenAddr)
+	if err := http.ListenAndServeTLS(listenAddr, certFile, keyFile, nil); err != nil {
+		klog.Fatalf("Webhook server exited: %v", err)
+	}
+}
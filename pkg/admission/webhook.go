@@ -0,0 +1,121 @@
+// Package admission implements a mutating admission webhook that stamps
+// watched config resources with the identity of whoever changed them, so
+// storage.GitStorage can attribute its commits to a real user instead of the
+// operator's service identity.
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog"
+)
+
+// AnnotationKey is where the webhook records the UserInfo of whoever made
+// the change. storage.GitStorage reads it back and strips it before
+// persisting the resource, so the annotation itself doesn't churn the diff.
+const AnnotationKey = "config-history.openshift.io/last-modified-by"
+
+// UserInfo is the subset of admissionv1.UserInfo persisted into AnnotationKey.
+type UserInfo struct {
+	Username string   `json:"username"`
+	Groups   []string `json:"groups,omitempty"`
+	UID      string   `json:"uid,omitempty"`
+}
+
+// Handler is an http.Handler for a mutating AdmissionReview webhook.
+type Handler struct{}
+
+// NewHandler returns a Handler ready to be registered on a mux.
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	review, err := readAdmissionReview(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeAdmissionReview(w, review, h.Review(review.Request))
+}
+
+// Review builds the JSONPatch response that stamps req.UserInfo onto the
+// incoming object as AnnotationKey.
+func (h *Handler) Review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	info := UserInfo{Username: req.UserInfo.Username, Groups: req.UserInfo.Groups, UID: string(req.UserInfo.UID)}
+	annotation, err := json.Marshal(info)
+	if err != nil {
+		return deny(fmt.Errorf("unable to encode %s annotation: %v", AnnotationKey, err))
+	}
+
+	var obj unstructured.Unstructured
+	if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		return deny(fmt.Errorf("unable to decode admitted object: %v", err))
+	}
+
+	var patch []map[string]interface{}
+	if obj.GetAnnotations() == nil {
+		patch = append(patch, map[string]interface{}{
+			"op":    "add",
+			"path":  "/metadata/annotations",
+			"value": map[string]string{},
+		})
+	}
+	patch = append(patch, map[string]interface{}{
+		"op":    "add",
+		"path":  "/metadata/annotations/" + jsonPatchEscape(AnnotationKey),
+		"value": string(annotation),
+	})
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return deny(fmt.Errorf("unable to encode patch: %v", err))
+	}
+
+	patchType := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     patchBytes,
+		PatchType: &patchType,
+	}
+}
+
+func deny(err error) *admissionv1.AdmissionResponse {
+	klog.Warningf("Denying admission request: %v", err)
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result:  &metav1.Status{Message: err.Error()},
+	}
+}
+
+func readAdmissionReview(r *http.Request) (*admissionv1.AdmissionReview, error) {
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		return nil, fmt.Errorf("unable to decode admission review: %v", err)
+	}
+	if review.Request == nil {
+		return nil, fmt.Errorf("admission review carries no request")
+	}
+	return &review, nil
+}
+
+func writeAdmissionReview(w http.ResponseWriter, review *admissionv1.AdmissionReview, response *admissionv1.AdmissionResponse) {
+	response.UID = review.Request.UID
+	review.Response = response
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		klog.Warningf("Unable to encode admission review response: %v", err)
+	}
+}
+
+// jsonPatchEscape escapes "~" and "/" per RFC 6901 so key is safe to use as
+// a JSON Pointer path segment.
+func jsonPatchEscape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,146 @@
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func admissionReview(t *testing.T, username string, groups []string, annotations map[string]interface{}) *admissionv1.AdmissionReview {
+	t.Helper()
+	obj := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": "test-config",
+		},
+	}
+	if annotations != nil {
+		obj["metadata"].(map[string]interface{})["annotations"] = annotations
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("unable to encode object: %v", err)
+	}
+
+	return &admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{
+			UID:      types.UID("00000000-0000-0000-0000-000000000001"),
+			Object:   runtime.RawExtension{Raw: raw},
+			UserInfo: authenticationv1.UserInfo{Username: username, Groups: groups},
+		},
+	}
+}
+
+func TestHandlerReviewAddsAnnotation(t *testing.T) {
+	h := NewHandler()
+	review := admissionReview(t, "alice", []string{"cluster-admins"}, nil)
+
+	resp := h.Review(review.Request)
+	if !resp.Allowed {
+		t.Fatalf("expected request to be allowed, got denied: %v", resp.Result)
+	}
+
+	var patch []map[string]interface{}
+	if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+		t.Fatalf("unable to decode patch: %v", err)
+	}
+
+	var annotationOp map[string]interface{}
+	for _, op := range patch {
+		if op["path"] == "/metadata/annotations/"+jsonPatchEscape(AnnotationKey) {
+			annotationOp = op
+		}
+	}
+	if annotationOp == nil {
+		t.Fatalf("patch did not add %s: %v", AnnotationKey, patch)
+	}
+
+	var info UserInfo
+	if err := json.Unmarshal([]byte(annotationOp["value"].(string)), &info); err != nil {
+		t.Fatalf("unable to decode annotation value: %v", err)
+	}
+	if info.Username != "alice" || len(info.Groups) != 1 || info.Groups[0] != "cluster-admins" {
+		t.Fatalf("unexpected UserInfo in annotation: %+v", info)
+	}
+}
+
+func TestHandlerReviewCreatesAnnotationsMapWhenMissing(t *testing.T) {
+	h := NewHandler()
+	review := admissionReview(t, "alice", nil, nil)
+
+	resp := h.Review(review.Request)
+	var patch []map[string]interface{}
+	if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+		t.Fatalf("unable to decode patch: %v", err)
+	}
+	if len(patch) != 2 {
+		t.Fatalf("expected a patch that both creates the annotations map and adds the annotation, got %v", patch)
+	}
+	if patch[0]["path"] != "/metadata/annotations" || patch[0]["op"] != "add" {
+		t.Fatalf("expected the first op to create /metadata/annotations, got %v", patch[0])
+	}
+}
+
+func TestHandlerReviewReusesExistingAnnotationsMap(t *testing.T) {
+	h := NewHandler()
+	review := admissionReview(t, "alice", nil, map[string]interface{}{"existing": "annotation"})
+
+	resp := h.Review(review.Request)
+	var patch []map[string]interface{}
+	if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+		t.Fatalf("unable to decode patch: %v", err)
+	}
+	if len(patch) != 1 {
+		t.Fatalf("expected a single op when annotations already exist, got %v", patch)
+	}
+}
+
+func TestHandlerServeHTTP(t *testing.T) {
+	h := NewHandler()
+	review := admissionReview(t, "bob", nil, nil)
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatalf("unable to encode admission review: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/mutate", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var got admissionv1.AdmissionReview
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("unable to decode response: %v", err)
+	}
+	if got.Response == nil {
+		t.Fatal("response carries no AdmissionResponse")
+	}
+	if !got.Response.Allowed {
+		t.Fatalf("expected request to be allowed, got denied: %v", got.Response.Result)
+	}
+	if got.Response.UID != review.Request.UID {
+		t.Fatalf("response UID %q does not match request UID %q", got.Response.UID, review.Request.UID)
+	}
+}
+
+func TestHandlerServeHTTPRejectsMalformedBody(t *testing.T) {
+	h := NewHandler()
+	req := httptest.NewRequest(http.MethodPost, "/mutate", bytes.NewReader([]byte("not json")))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
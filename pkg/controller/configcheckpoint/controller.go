@@ -0,0 +1,89 @@
+// Package configcheckpoint drives storage.GitStorage.Checkpoint (see its
+// doc comment for the CLI-facing use case) from ConfigCheckpoint resources.
+package configcheckpoint
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// Resource identifies ConfigCheckpoint for the dynamic client used to watch
+// it and to patch status.tagHash after a successful checkpoint.
+var Resource = schema.GroupVersionResource{
+	Group:    "config-history.openshift.io",
+	Version:  "v1alpha1",
+	Resource: "configcheckpoints",
+}
+
+// Checkpointer is the subset of storage.GitStorage's checkpoint API the
+// controller drives.
+type Checkpointer interface {
+	Checkpoint(name, message string) (string, error)
+}
+
+// Controller creates a checkpoint tag for every ConfigCheckpoint resource
+// added to the cluster, and records the resulting tag hash on its status.
+type Controller struct {
+	storage Checkpointer
+	client  dynamic.Interface
+}
+
+// NewController returns a cache.ResourceEventHandler that tags storage's
+// HEAD whenever a ConfigCheckpoint is created. ConfigCheckpoint is treated
+// as a write-once bookmark, so updates and deletes are ignored.
+func NewController(storage Checkpointer, client dynamic.Interface) cache.ResourceEventHandler {
+	return &Controller{storage: storage, client: client}
+}
+
+func (c *Controller) OnAdd(obj interface{}) {
+	checkpoint, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		klog.Warningf("Unable to decode ConfigCheckpoint: not unstructured")
+		return
+	}
+
+	// The informer's initial relist re-delivers OnAdd for every
+	// pre-existing ConfigCheckpoint on every controller restart. Skip
+	// ones already tagged in a prior process lifetime instead of letting
+	// Checkpoint fail on the tag it already created.
+	if tagHash, _, _ := unstructured.NestedString(checkpoint.Object, "status", "tagHash"); tagHash != "" {
+		return
+	}
+
+	message, _, err := unstructured.NestedString(checkpoint.Object, "spec", "message")
+	if err != nil {
+		klog.Warningf("Unable to read %q spec.message: %v", checkpoint.GetName(), err)
+	}
+
+	hash, err := c.storage.Checkpoint(checkpoint.GetName(), message)
+	if err != nil {
+		klog.Warningf("Unable to create checkpoint %q: %v", checkpoint.GetName(), err)
+		return
+	}
+	klog.Infof("Created checkpoint %q at tag %q", checkpoint.GetName(), hash)
+
+	if err := c.setTagHash(checkpoint, hash); err != nil {
+		klog.Warningf("Unable to record status.tagHash for checkpoint %q: %v", checkpoint.GetName(), err)
+	}
+}
+
+// setTagHash patches checkpoint's status.tagHash to hash via the status
+// subresource.
+func (c *Controller) setTagHash(checkpoint *unstructured.Unstructured, hash string) error {
+	updated := checkpoint.DeepCopy()
+	if err := unstructured.SetNestedField(updated.Object, hash, "status", "tagHash"); err != nil {
+		return err
+	}
+	_, err := c.client.Resource(Resource).UpdateStatus(context.TODO(), updated, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *Controller) OnUpdate(_, _ interface{}) {}
+
+func (c *Controller) OnDelete(_ interface{}) {}
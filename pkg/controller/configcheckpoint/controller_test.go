@@ -0,0 +1,88 @@
+package configcheckpoint
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+// fakeCheckpointer is a Checkpointer that records every call it receives, so
+// tests can assert whether OnAdd invoked Checkpoint at all.
+type fakeCheckpointer struct {
+	calls []string
+	hash  string
+	err   error
+}
+
+func (f *fakeCheckpointer) Checkpoint(name, message string) (string, error) {
+	f.calls = append(f.calls, name)
+	return f.hash, f.err
+}
+
+func configCheckpoint(name, tagHash string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "config-history.openshift.io/v1alpha1",
+			"kind":       "ConfigCheckpoint",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{
+				"message": "before the upgrade",
+			},
+		},
+	}
+	if tagHash != "" {
+		obj.Object["status"] = map[string]interface{}{"tagHash": tagHash}
+	}
+	return obj
+}
+
+// TestControllerSkipsAlreadyCheckpointed confirms OnAdd does not call
+// Checkpoint again for a ConfigCheckpoint whose status.tagHash is already
+// set, the case the informer's initial relist re-delivers on every
+// controller restart.
+func TestControllerSkipsAlreadyCheckpointed(t *testing.T) {
+	checkpointer := &fakeCheckpointer{hash: "deadbeef"}
+	controller := NewController(checkpointer, fake.NewSimpleDynamicClient(runtime.NewScheme())).(*Controller)
+
+	controller.OnAdd(configCheckpoint("pre-4.15-upgrade", "already-tagged-hash"))
+
+	if len(checkpointer.calls) != 0 {
+		t.Fatalf("Checkpoint was called %v, want no calls for an already-tagged ConfigCheckpoint", checkpointer.calls)
+	}
+}
+
+// TestControllerCheckpointsAndRecordsTagHash confirms OnAdd creates a
+// checkpoint and patches status.tagHash for a ConfigCheckpoint that hasn't
+// been tagged yet.
+func TestControllerCheckpointsAndRecordsTagHash(t *testing.T) {
+	checkpointer := &fakeCheckpointer{hash: "deadbeef"}
+	gvrToListKind := map[schema.GroupVersionResource]string{Resource: "ConfigCheckpointList"}
+	obj := configCheckpoint("pre-4.15-upgrade", "")
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, obj)
+
+	controller := NewController(checkpointer, client).(*Controller)
+	controller.OnAdd(obj)
+
+	if len(checkpointer.calls) != 1 || checkpointer.calls[0] != "pre-4.15-upgrade" {
+		t.Fatalf("Checkpoint calls = %v, want one call for %q", checkpointer.calls, "pre-4.15-upgrade")
+	}
+
+	updated, err := client.Resource(Resource).Get(context.TODO(), "pre-4.15-upgrade", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to fetch updated ConfigCheckpoint: %v", err)
+	}
+	tagHash, _, err := unstructured.NestedString(updated.Object, "status", "tagHash")
+	if err != nil {
+		t.Fatalf("unable to read status.tagHash: %v", err)
+	}
+	if tagHash != "deadbeef" {
+		t.Fatalf("status.tagHash = %q, want %q", tagHash, "deadbeef")
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/damemi/config-history-operator/pkg/admission"
+)
+
+// TestSanitizeUserInfoRejectsUnsafeCharacters confirms a Username or Group
+// containing a control character or angle bracket is rejected wholesale
+// rather than partially trusted, since either could inject extra header
+// lines into the raw commit object via object.Signature.Encode.
+func TestSanitizeUserInfoRejectsUnsafeCharacters(t *testing.T) {
+	cases := []struct {
+		name string
+		info admission.UserInfo
+	}{
+		{"newline in username", admission.UserInfo{Username: "alice\ngpgsig fake"}},
+		{"carriage return in username", admission.UserInfo{Username: "alice\rcommitter evil"}},
+		{"angle brackets in username", admission.UserInfo{Username: "alice><script>"}},
+		{"newline in group", admission.UserInfo{Username: "alice", Groups: []string{"devs\nparent deadbeef"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := sanitizeUserInfo(c.info)
+			if got.Username != "" || len(got.Groups) != 0 || got.UID != "" {
+				t.Fatalf("sanitizeUserInfo(%+v) = %+v, want the zero value", c.info, got)
+			}
+		})
+	}
+}
+
+// TestSanitizeUserInfoAllowsOrdinaryValues confirms sanitizeUserInfo doesn't
+// reject legitimate identities.
+func TestSanitizeUserInfoAllowsOrdinaryValues(t *testing.T) {
+	info := admission.UserInfo{Username: "alice@example.com", Groups: []string{"system:authenticated", "devs"}}
+	got := sanitizeUserInfo(info)
+	if got.Username != info.Username || strings.Join(got.Groups, ",") != strings.Join(info.Groups, ",") {
+		t.Fatalf("sanitizeUserInfo(%+v) = %+v, want it unchanged", info, got)
+	}
+}
+
+// TestGitStorageFallsBackOnUnsafeUsername confirms OnAdd falls back to the
+// operator identity, rather than embedding attacker-controlled bytes into
+// the commit's author/committer signature or its trailer, when the
+// admission annotation carries a Username crafted to inject extra commit
+// object header lines.
+func TestGitStorageFallsBackOnUnsafeUsername(t *testing.T) {
+	storage, err := NewGitStorage(t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("unable to create GitStorage: %v", err)
+	}
+	gitStorage := storage.(*GitStorage)
+
+	malicious := "mallory\ngpgsig -----BEGIN PGP SIGNATURE-----"
+	annotation, err := json.Marshal(admission.UserInfo{Username: malicious})
+	if err != nil {
+		t.Fatalf("unable to encode annotation: %v", err)
+	}
+	gitStorage.OnAdd(configMapWithAnnotation("test-config", string(annotation)))
+
+	head, err := gitStorage.repo.Head()
+	if err != nil {
+		t.Fatalf("unable to resolve HEAD: %v", err)
+	}
+	commit, err := gitStorage.repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("unable to load HEAD commit: %v", err)
+	}
+
+	if commit.Author.Name != "operator" {
+		t.Fatalf("commit author = %q, want fallback %q", commit.Author.Name, "operator")
+	}
+	if strings.Contains(commit.Message, "gpgsig") || strings.Contains(commit.Message, "mallory") {
+		t.Fatalf("commit message = %q, want no trace of the rejected username", commit.Message)
+	}
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// Backend is a pluggable store for config resource history. GitStorage and
+// ObjectStorage both implement it, selected at startup by the scheme of a
+// storage URI via NewBackend.
+type Backend interface {
+	cache.ResourceEventHandler
+
+	// Snapshot returns the most recently stored content for the tracked
+	// resource file named name.
+	Snapshot(name string) ([]byte, error)
+
+	// List returns the names of all resource files currently tracked.
+	List() ([]string, error)
+}
+
+// NewBackend selects and constructs a Backend from a storage URI. A
+// file:///var/lib/history (or bare /var/lib/history) URI keeps the existing
+// go-git behavior; s3://bucket/prefix and gs://bucket/prefix store each
+// revision as an object, for clusters that can't dedicate a PVC/git server to
+// the operator. signer and remoteCfg are only meaningful for the git
+// backend and are ignored otherwise.
+func NewBackend(uri string, signer SignerProvider, remoteCfg *RemoteConfig) (Backend, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage backend URI %q: %v", uri, err)
+	}
+
+	switch parsed.Scheme {
+	case "", "file":
+		return NewGitStorage(parsed.Path, signer, remoteCfg)
+	case "s3":
+		client, err := newS3Client(parsed.Host)
+		if err != nil {
+			return nil, err
+		}
+		return NewObjectStorage(client, strings.TrimPrefix(parsed.Path, "/")), nil
+	case "gs":
+		client, err := newGCSClient(parsed.Host)
+		if err != nil {
+			return nil, err
+		}
+		return NewObjectStorage(client, strings.TrimPrefix(parsed.Path, "/")), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage backend scheme %q in %q", parsed.Scheme, uri)
+	}
+}
+
+// Snapshot returns the content of name's file as of HEAD.
+func (s *GitStorage) Snapshot(name string) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	head, err := s.repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := s.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	f, err := tree.File(name)
+	if err != nil {
+		if err == object.ErrFileNotFound {
+			return nil, fmt.Errorf("%q is not tracked", name)
+		}
+		return nil, err
+	}
+	content, err := f.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+// List returns the names of every resource file tracked at HEAD.
+func (s *GitStorage) List() ([]string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	head, err := s.repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	commit, err := s.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if err := tree.Files().ForEach(func(f *object.File) error {
+		names = append(names, f.Name)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+)
+
+// ResourceChange describes one resource file that differs between two
+// checkpoints.
+type ResourceChange struct {
+	Name   string
+	Change string // "added", "modified" or "deleted"
+}
+
+// Checkpoint creates an annotated tag called name, pointing at HEAD, so
+// operators can bookmark cluster-upgrade and change-window boundaries in the
+// config timeline (e.g. `kubectl create configcheckpoint
+// pre-4.15-upgrade`).
+func (s *GitStorage) Checkpoint(name, message string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	head, err := s.repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	tagger := &object.Signature{
+		Name:  "config-history-operator",
+		Email: "config-history-operator@openshift.io",
+		When:  time.Now(),
+	}
+	opts := &git.CreateTagOptions{Tagger: tagger, Message: message}
+
+	if s.signer != nil {
+		entity, err := s.signer.Entity()
+		if err != nil {
+			return "", fmt.Errorf("unable to load signing key: %v", err)
+		}
+		if n, email := signerIdentity(entity); n != "" {
+			tagger.Name, tagger.Email = n, email
+		}
+		opts.SignKey = entity
+	}
+
+	tagRef, err := s.repo.CreateTag(name, head.Hash(), opts)
+	if err != nil {
+		return "", err
+	}
+	s.updateRefsFile()
+	return tagRef.Hash().String(), nil
+}
+
+// ListCheckpoints returns the names of every checkpoint tag, sorted
+// alphabetically.
+func (s *GitStorage) ListCheckpoints() ([]string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tags, err := s.repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := tags.ForEach(func(ref *plumbing.Reference) error {
+		names = append(names, ref.Name().Short())
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// DiffCheckpoints reports which resource files were added, modified or
+// deleted between checkpoints a and b.
+func (s *GitStorage) DiffCheckpoints(a, b string) ([]ResourceChange, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	commitA, err := s.resolveCheckpoint(a)
+	if err != nil {
+		return nil, err
+	}
+	commitB, err := s.resolveCheckpoint(b)
+	if err != nil {
+		return nil, err
+	}
+
+	treeA, err := commitA.Tree()
+	if err != nil {
+		return nil, err
+	}
+	treeB, err := commitB.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := treeA.Diff(treeB)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ResourceChange, 0, len(changes))
+	for _, c := range changes {
+		action, err := c.Action()
+		if err != nil {
+			return nil, err
+		}
+		name := c.To.Name
+		if name == "" {
+			name = c.From.Name
+		}
+		result = append(result, ResourceChange{Name: name, Change: changeActionString(action)})
+	}
+	return result, nil
+}
+
+// resolveCheckpoint resolves a checkpoint tag (or any other revision, such
+// as a commit hash or branch name) to the commit it points at.
+func (s *GitStorage) resolveCheckpoint(ref string) (*object.Commit, error) {
+	hash, err := s.repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve checkpoint %q: %v", ref, err)
+	}
+	return s.repo.CommitObject(*hash)
+}
+
+func changeActionString(action merkletrie.Action) string {
+	switch action {
+	case merkletrie.Insert:
+		return "added"
+	case merkletrie.Delete:
+		return "deleted"
+	case merkletrie.Modify:
+		return "modified"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestGitStorageCheckpointTagsHeadAndLists confirms Checkpoint creates an
+// annotated tag at HEAD and that ListCheckpoints reports it back,
+// alphabetically sorted.
+func TestGitStorageCheckpointTagsHeadAndLists(t *testing.T) {
+	storage, err := NewGitStorage(t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("unable to create GitStorage: %v", err)
+	}
+	gitStorage := storage.(*GitStorage)
+
+	gitStorage.OnAdd(configMap("test-config"))
+
+	head, err := gitStorage.repo.Head()
+	if err != nil {
+		t.Fatalf("unable to resolve HEAD: %v", err)
+	}
+
+	hash, err := gitStorage.Checkpoint("zeta-checkpoint", "before the zeta change window")
+	if err != nil {
+		t.Fatalf("Checkpoint returned error: %v", err)
+	}
+	if hash == "" {
+		t.Fatal("Checkpoint returned an empty tag hash")
+	}
+	if _, err := gitStorage.Checkpoint("alpha-checkpoint", "before the alpha upgrade"); err != nil {
+		t.Fatalf("Checkpoint returned error: %v", err)
+	}
+
+	tagRef, err := gitStorage.resolveCheckpoint("zeta-checkpoint")
+	if err != nil {
+		t.Fatalf("resolveCheckpoint returned error: %v", err)
+	}
+	if tagRef.Hash != head.Hash() {
+		t.Fatalf("zeta-checkpoint resolves to %s, want HEAD %s", tagRef.Hash, head.Hash())
+	}
+
+	names, err := gitStorage.ListCheckpoints()
+	if err != nil {
+		t.Fatalf("ListCheckpoints returned error: %v", err)
+	}
+	want := []string{"alpha-checkpoint", "zeta-checkpoint"}
+	if !sort.StringsAreSorted(names) || strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("ListCheckpoints = %v, want %v", names, want)
+	}
+}
+
+// TestGitStorageDiffCheckpointsReportsChanges confirms DiffCheckpoints
+// reports added, modified, and deleted resource files between two
+// checkpoint tags.
+func TestGitStorageDiffCheckpointsReportsChanges(t *testing.T) {
+	storage, err := NewGitStorage(t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("unable to create GitStorage: %v", err)
+	}
+	gitStorage := storage.(*GitStorage)
+
+	gitStorage.OnAdd(resourceOfKind("ConfigMap", "v1"))
+	gitStorage.OnAdd(resourceOfKind("Secret", "v1"))
+	if _, err := gitStorage.Checkpoint("before", "before the change window"); err != nil {
+		t.Fatalf("Checkpoint returned error: %v", err)
+	}
+
+	gitStorage.OnUpdate(nil, resourceOfKind("ConfigMap", "v2"))
+	gitStorage.OnDelete(resourceOfKind("Secret", "v1"))
+	gitStorage.OnAdd(resourceOfKind("Namespace", "v1"))
+	if _, err := gitStorage.Checkpoint("after", "after the change window"); err != nil {
+		t.Fatalf("Checkpoint returned error: %v", err)
+	}
+
+	changes, err := gitStorage.DiffCheckpoints("before", "after")
+	if err != nil {
+		t.Fatalf("DiffCheckpoints returned error: %v", err)
+	}
+
+	got := make(map[string]string, len(changes))
+	for _, c := range changes {
+		got[c.Name] = c.Change
+	}
+	want := map[string]string{
+		resourceFilename(resourceOfKind("ConfigMap", "v2").GroupVersionKind()): "modified",
+		resourceFilename(resourceOfKind("Secret", "v1").GroupVersionKind()):    "deleted",
+		resourceFilename(resourceOfKind("Namespace", "v1").GroupVersionKind()): "added",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("DiffCheckpoints returned %d changes, want %d: %v", len(got), len(want), changes)
+	}
+	for name, change := range want {
+		if got[name] != change {
+			t.Fatalf("DiffCheckpoints[%q] = %q, want %q", name, got[name], change)
+		}
+	}
+}
+
+// TestGitStorageCheckpointUpdatesRefsFile confirms Checkpoint's tag is
+// visible in .git/info/refs, since that's what the HTTP clone path serves.
+func TestGitStorageCheckpointUpdatesRefsFile(t *testing.T) {
+	repoPath := t.TempDir()
+	storage, err := NewGitStorage(repoPath, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to create GitStorage: %v", err)
+	}
+	gitStorage := storage.(*GitStorage)
+
+	gitStorage.OnAdd(configMap("test-config"))
+	if _, err := gitStorage.Checkpoint("pre-upgrade", "before the upgrade"); err != nil {
+		t.Fatalf("Checkpoint returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(repoPath, ".git", "info", "refs"))
+	if err != nil {
+		t.Fatalf("unable to read .git/info/refs: %v", err)
+	}
+	if !strings.Contains(string(data), "refs/tags/pre-upgrade") {
+		t.Fatalf(".git/info/refs = %q, want it to contain refs/tags/pre-upgrade", data)
+	}
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// gcsClient adapts the Google Cloud Storage SDK to objectClient.
+type gcsClient struct {
+	bucket *storage.BucketHandle
+}
+
+// newGCSClient builds a gcsClient for bucket, using application default
+// credentials.
+func newGCSClient(bucket string) (*gcsClient, error) {
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &gcsClient{bucket: client.Bucket(bucket)}, nil
+}
+
+func (c *gcsClient) Put(ctx context.Context, key string, data []byte) error {
+	w := c.bucket.Object(key).NewWriter(ctx)
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (c *gcsClient) Get(ctx context.Context, key string) ([]byte, error) {
+	r, err := c.bucket.Object(key).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, errObjectNotFound
+		}
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func (c *gcsClient) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	it := c.bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, attrs.Name)
+	}
+	return keys, nil
+}
@@ -1,10 +1,12 @@
 package storage
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -12,26 +14,42 @@ import (
 	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+
+	"golang.org/x/crypto/openpgp"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 	"sigs.k8s.io/yaml"
+
+	"github.com/damemi/config-history-operator/pkg/admission"
 )
 
 type GitStorage struct {
 	repo *git.Repository
 	path string
 
+	// signer, when set, PGP-signs every commit produced by this storage. It
+	// is nil by default, which leaves commits unsigned.
+	signer SignerProvider
+
+	// mirrorAuth and pushCh back the offsite mirror push; both are nil when
+	// no RemoteConfig was configured.
+	mirrorAuth     transport.AuthMethod
+	pushCh         chan struct{}
+	lastPushStatus PushStatus
+
 	// The storage must be synchronized.
 	sync.Mutex
 }
 
 // NewGitStorage initialize the GIT based storage. Using this storage, every change to the config
-// resource is recorded as a commit into GIT database.
-func NewGitStorage(path string) (cache.ResourceEventHandler, error) {
+// resource is recorded as a commit into GIT database. When signer is non-nil, every commit is
+// PGP-signed using it; pass nil to leave commits unsigned. When remoteCfg is non-nil, every
+// commit is asynchronously pushed to the configured mirror.
+func NewGitStorage(path string, signer SignerProvider, remoteCfg *RemoteConfig) (Backend, error) {
 	// If the repo does not exists, do git init
 	if _, err := os.Stat(filepath.Join(path, ".git")); os.IsNotExist(err) {
 		_, err := git.PlainInit(path, false)
@@ -43,7 +61,11 @@ func NewGitStorage(path string) (cache.ResourceEventHandler, error) {
 	if err != nil {
 		return nil, err
 	}
-	storage := &GitStorage{path: path, repo: repo}
+	storage := &GitStorage{path: path, repo: repo, signer: signer}
+
+	if err := storage.setupMirror(remoteCfg); err != nil {
+		return nil, err
+	}
 
 	return storage, nil
 }
@@ -51,7 +73,7 @@ func NewGitStorage(path string) (cache.ResourceEventHandler, error) {
 func (s *GitStorage) OnAdd(obj interface{}) {
 	s.Lock()
 	defer s.Unlock()
-	name, content, err := decodeUnstructuredObject(obj)
+	name, content, userInfo, err := decodeUnstructuredObject(obj)
 	if err != nil {
 		klog.Warningf("Unable to decode %q: %v", name, err)
 		return
@@ -61,19 +83,19 @@ func (s *GitStorage) OnAdd(obj interface{}) {
 		return
 	}
 
-	// TODO: Use the "real" author here (this will need mutating admission that will record username into annotation)
-	hash, err := s.commit(name, "operator", fmt.Sprintf("%s added", name))
+	hash, err := s.commit(name, userInfo, fmt.Sprintf("%s added", name))
 	if err != nil {
 		klog.Warningf("Unable to commit file %q: %v", name, err)
 	}
 	s.updateRefsFile()
+	s.enqueuePush()
 	klog.Infof("Added %q in commit %q", name, hash)
 }
 
 func (s *GitStorage) OnUpdate(_, obj interface{}) {
 	s.Lock()
 	defer s.Unlock()
-	name, content, err := decodeUnstructuredObject(obj)
+	name, content, userInfo, err := decodeUnstructuredObject(obj)
 	if err != nil {
 		klog.Warningf("Unable to decode %q: %v", name, err)
 		return
@@ -83,19 +105,19 @@ func (s *GitStorage) OnUpdate(_, obj interface{}) {
 		return
 	}
 
-	// TODO: Use the "real" author here (this will need mutating admission that will record username into annotation)
-	hash, err := s.commit(name, "operator", fmt.Sprintf("%s modified", name))
+	hash, err := s.commit(name, userInfo, fmt.Sprintf("%s modified", name))
 	if err != nil {
 		klog.Warningf("Unable to commit file %q: %v", name, err)
 	}
 	s.updateRefsFile()
+	s.enqueuePush()
 	klog.Infof("Updated %q in commit %q", name, hash)
 }
 
 func (s *GitStorage) OnDelete(obj interface{}) {
 	s.Lock()
 	defer s.Unlock()
-	name, _, err := decodeUnstructuredObject(obj)
+	name, _, userInfo, err := decodeUnstructuredObject(obj)
 	if err != nil {
 		klog.Warningf("Unable to decode %q: %v", name, err)
 		return
@@ -104,33 +126,114 @@ func (s *GitStorage) OnDelete(obj interface{}) {
 		klog.Warningf("Unable to delete file %q: %v", name, err)
 		return
 	}
-	hash, err := s.commit(name, "operator", fmt.Sprintf("%q removed", name))
+	hash, err := s.commit(name, userInfo, fmt.Sprintf("%q removed", name))
 	if err != nil {
 		klog.Warningf("Unable to commit file %q: %v", name, err)
 	}
 	s.updateRefsFile()
+	s.enqueuePush()
 	klog.Infof("Deleted %q in commit %q", name, hash)
 }
 
-func decodeUnstructuredObject(obj interface{}) (string, []byte, error) {
+// decodeUnstructuredObject renders obj as the YAML that gets persisted, and
+// extracts the admission.AnnotationKey annotation (if any) left by the
+// mutating webhook. The annotation itself is stripped from the persisted
+// copy so it doesn't churn the diff on every change.
+func decodeUnstructuredObject(obj interface{}) (string, []byte, admission.UserInfo, error) {
 	objUnstructured := obj.(*unstructured.Unstructured)
 	filename := resourceFilename(objUnstructured.GroupVersionKind())
+
+	userInfo := extractUserInfo(objUnstructured)
+	objUnstructured = stripLastModifiedByAnnotation(objUnstructured)
+
 	objectBytes, err := runtime.Encode(unstructured.UnstructuredJSONScheme, objUnstructured)
 	if err != nil {
-		return filename, nil, err
+		return filename, nil, userInfo, err
 	}
 	objectYAML, err := yaml.JSONToYAML(objectBytes)
 	if err != nil {
-		return filename, nil, err
+		return filename, nil, userInfo, err
+	}
+	return filename, objectYAML, userInfo, err
+}
+
+// extractUserInfo decodes the admission.AnnotationKey annotation left by the
+// mutating webhook. It returns the zero value when the annotation is absent
+// or malformed, so callers fall back to the "operator" identity.
+func extractUserInfo(obj *unstructured.Unstructured) admission.UserInfo {
+	raw, ok := obj.GetAnnotations()[admission.AnnotationKey]
+	if !ok {
+		return admission.UserInfo{}
+	}
+	var info admission.UserInfo
+	if err := json.Unmarshal([]byte(raw), &info); err != nil {
+		klog.Warningf("Unable to decode %s annotation: %v", admission.AnnotationKey, err)
+		return admission.UserInfo{}
+	}
+	return sanitizeUserInfo(info)
+}
+
+// unsafeIdentityChars matches control characters (including CR/LF) and the
+// angle brackets go-git's Signature.Encode wraps Email in. Kubernetes
+// doesn't restrict UserInfo.Username/Groups to a safe character set for
+// non-ServiceAccount identities, so any of these would let a crafted
+// username or group inject extra header lines into the raw commit object
+// attributedComponent and appendUserTrailer feed into.
+var unsafeIdentityChars = regexp.MustCompile(`[\x00-\x1f\x7f<>]`)
+
+// sanitizeUserInfo rejects a UserInfo whose Username or any Group contains
+// unsafeIdentityChars, returning the zero value so callers fall back to the
+// operator's own identity the same way a missing annotation does.
+func sanitizeUserInfo(userInfo admission.UserInfo) admission.UserInfo {
+	if unsafeIdentityChars.MatchString(userInfo.Username) {
+		klog.Warningf("Ignoring admission UserInfo with unsafe username %q", userInfo.Username)
+		return admission.UserInfo{}
+	}
+	for _, group := range userInfo.Groups {
+		if unsafeIdentityChars.MatchString(group) {
+			klog.Warningf("Ignoring admission UserInfo with unsafe group %q", group)
+			return admission.UserInfo{}
+		}
+	}
+	return userInfo
+}
+
+// attributedComponent returns the identity a commit or revision should be
+// attributed to: userInfo's username when the mutating webhook recorded one,
+// falling back to the operator's own "operator" identity otherwise.
+func attributedComponent(userInfo admission.UserInfo) string {
+	if userInfo.Username != "" {
+		return userInfo.Username
 	}
-	return filename, objectYAML, err
+	return "operator"
+}
+
+// stripLastModifiedByAnnotation returns a copy of obj with the
+// admission.AnnotationKey annotation removed, leaving obj itself untouched.
+func stripLastModifiedByAnnotation(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[admission.AnnotationKey]; !ok {
+		return obj
+	}
+	out := obj.DeepCopy()
+	annotations = out.GetAnnotations()
+	delete(annotations, admission.AnnotationKey)
+	out.SetAnnotations(annotations)
+	return out
 }
 
 func resourceFilename(gvk schema.GroupVersionKind) string {
 	return strings.ToLower(fmt.Sprintf("%s.%s.%s.yaml", gvk.Kind, gvk.Version, gvk.Group))
 }
 
-func (s *GitStorage) commit(name, component, message string) (string, error) {
+// commit records the worktree's pending changes to name. The commit is
+// attributed to userInfo's username when the mutating webhook recorded one,
+// falling back to the operator's own "operator" identity otherwise. When a
+// signer is configured the commit is PGP-signed either way, and without a
+// recorded username the author is the signing key's identity instead of
+// "operator". The message gets an X-Config-Change-User trailer only when a
+// username was recorded.
+func (s *GitStorage) commit(name string, userInfo admission.UserInfo, message string) (string, error) {
 	t, err := s.repo.Worktree()
 	if err != nil {
 		return "", err
@@ -145,25 +248,59 @@ func (s *GitStorage) commit(name, component, message string) (string, error) {
 	if _, err := t.Add(name); err != nil {
 		return "", err
 	}
-	hash, err := t.Commit(message, &git.CommitOptions{
-		All: true,
-		Author: &object.Signature{
-			Name:  "config-history-operator",
-			Email: "config-history-operator@openshift.io",
-			When:  time.Now(),
-		},
+
+	component := attributedComponent(userInfo)
+	author := &object.Signature{
+		Name:  component,
+		Email: component + "@openshift.io",
+		When:  time.Now(),
+	}
+	opts := &git.CommitOptions{
+		All:    true,
+		Author: author,
 		Committer: &object.Signature{
-			Name:  component,
-			Email: component + "@openshift.io",
+			Name:  "operator",
+			Email: "operator@openshift.io",
 			When:  time.Now(),
 		},
-	})
+	}
+
+	var entity *openpgp.Entity
+	if s.signer != nil {
+		var err error
+		entity, err = s.signer.Entity()
+		if err != nil {
+			return "", fmt.Errorf("unable to load signing key: %v", err)
+		}
+		if userInfo.Username == "" {
+			if name, email := signerIdentity(entity); name != "" {
+				author.Name, author.Email = name, email
+			}
+		}
+		opts.SignKey = entity
+	}
+
+	hash, err := t.Commit(appendUserTrailer(message, userInfo), opts)
 	if err != nil {
 		return "", err
 	}
 	return hash.String(), err
 }
 
+// appendUserTrailer adds an X-Config-Change-User trailer naming userInfo's
+// username and groups, the way Signed-off-by trailers are appended. It is a
+// no-op when no username was recorded.
+func appendUserTrailer(message string, userInfo admission.UserInfo) string {
+	if userInfo.Username == "" {
+		return message
+	}
+	trailer := fmt.Sprintf("X-Config-Change-User: %s", userInfo.Username)
+	if len(userInfo.Groups) > 0 {
+		trailer += fmt.Sprintf(" (groups: %s)", strings.Join(userInfo.Groups, ","))
+	}
+	return message + "\n\n" + trailer
+}
+
 func (s *GitStorage) delete(name string) error {
 	t, err := s.repo.Worktree()
 	if err != nil {
@@ -199,14 +336,24 @@ func (s *GitStorage) write(name string, content []byte) error {
 	return s.write(name, content)
 }
 
-// updateRefsFile populate .git/info/refs which is needed for git clone HTTP server
+// updateRefsFile populate .git/info/refs which is needed for git clone HTTP server.
+// This includes refs/tags/* (e.g. checkpoints created via Checkpoint), with an
+// extra peeled "^{}" line for annotated tags pointing at the commit they
+// tag, the way `git update-server-info` does.
 func (s *GitStorage) updateRefsFile() {
 	refs, _ := s.repo.References()
 	var data []byte
 	err := refs.ForEach(func(ref *plumbing.Reference) error {
-		if ref.Type() == plumbing.HashReference {
-			s := ref.Strings()
-			data = append(data, []byte(fmt.Sprintf("%s\t%s\n", s[1], s[0]))...)
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		strs := ref.Strings()
+		data = append(data, []byte(fmt.Sprintf("%s\t%s\n", strs[1], strs[0]))...)
+
+		if tag, err := s.repo.TagObject(ref.Hash()); err == nil {
+			if commit, err := tag.Commit(); err == nil {
+				data = append(data, []byte(fmt.Sprintf("%s\t%s^{}\n", commit.Hash.String(), strs[0]))...)
+			}
 		}
 		return nil
 	})
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Revision describes a single commit that changed a tracked resource's file.
+type Revision struct {
+	Hash    string
+	Author  object.Signature
+	Message string
+}
+
+// HistoryOptions narrows the range of commits History walks.
+type HistoryOptions struct {
+	// Since, if non-zero, excludes commits authored before this time.
+	Since time.Time
+	// Until, if non-zero, excludes commits authored after this time.
+	Until time.Time
+	// Limit, if > 0, caps the number of revisions returned, most recent first.
+	Limit int
+}
+
+// History returns the commits that changed the file tracking the named
+// resource, most recent first. name is accepted for forward-compatibility
+// with multi-instance resources; resourceFilename currently maps a GVK to a
+// single path, since the operator only tracks cluster-scoped singleton
+// config resources today.
+func (s *GitStorage) History(gvk schema.GroupVersionKind, name string, opts HistoryOptions) ([]Revision, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	path := resourceFilename(gvk)
+
+	head, err := s.repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := s.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []Revision
+	for commit != nil {
+		if !opts.Since.IsZero() && commit.Author.When.Before(opts.Since) {
+			break
+		}
+		parent, err := firstParent(commit)
+		if err != nil {
+			return nil, err
+		}
+
+		if !opts.Until.IsZero() && commit.Author.When.After(opts.Until) {
+			commit = parent
+			continue
+		}
+
+		hash, present, err := blobHashForPath(commit, path)
+		if err != nil {
+			return nil, err
+		}
+		var parentHash plumbing.Hash
+		var parentPresent bool
+		if parent != nil {
+			parentHash, parentPresent, err = blobHashForPath(parent, path)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if present != parentPresent || (present && parentPresent && hash != parentHash) {
+			revisions = append(revisions, Revision{
+				Hash:    commit.Hash.String(),
+				Author:  commit.Author,
+				Message: commit.Message,
+			})
+			if opts.Limit > 0 && len(revisions) >= opts.Limit {
+				break
+			}
+		}
+
+		commit = parent
+	}
+	return revisions, nil
+}
+
+// At reconstructs the contents of the file tracking the named resource as of
+// hash. name is accepted for the same forward-compatibility reason as in
+// History.
+func (s *GitStorage) At(gvk schema.GroupVersionKind, name string, hash string) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	path := resourceFilename(gvk)
+
+	commit, err := s.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	f, err := tree.File(path)
+	if err != nil {
+		if err == object.ErrFileNotFound {
+			return nil, fmt.Errorf("%s does not exist at commit %s", path, hash)
+		}
+		return nil, err
+	}
+	content, err := f.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+// firstParent returns commit's first parent, or nil if commit is the root.
+func firstParent(commit *object.Commit) (*object.Commit, error) {
+	if commit.NumParents() == 0 {
+		return nil, nil
+	}
+	return commit.Parent(0)
+}
+
+// blobHashForPath resolves the blob hash of path in commit's tree. present is
+// false when the path does not exist in that tree.
+func blobHashForPath(commit *object.Commit, path string) (hash plumbing.Hash, present bool, err error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, false, err
+	}
+	f, err := tree.File(path)
+	if err != nil {
+		if err == object.ErrFileNotFound {
+			return plumbing.ZeroHash, false, nil
+		}
+		return plumbing.ZeroHash, false, err
+	}
+	return f.Hash, true, nil
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGitStorageHistoryWalksAndLimits records three revisions of the same
+// resource and confirms History returns them most-recent-first and honors
+// Limit, and that At reconstructs an older revision's content.
+func TestGitStorageHistoryWalksAndLimits(t *testing.T) {
+	storage, err := NewGitStorage(t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("unable to create GitStorage: %v", err)
+	}
+	gitStorage := storage.(*GitStorage)
+
+	gitStorage.OnAdd(configMapWithData("test-config", "v1"))
+	time.Sleep(2 * time.Millisecond)
+	gitStorage.OnUpdate(nil, configMapWithData("test-config", "v2"))
+	time.Sleep(2 * time.Millisecond)
+	gitStorage.OnUpdate(nil, configMapWithData("test-config", "v3"))
+
+	gvk := configMapWithData("test-config", "v3").GroupVersionKind()
+
+	revisions, err := gitStorage.History(gvk, "test-config", HistoryOptions{})
+	if err != nil {
+		t.Fatalf("History returned error: %v", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("got %d revisions, want 3", len(revisions))
+	}
+	if !strings.Contains(revisions[0].Message, "modified") {
+		t.Fatalf("revisions[0].Message = %q, want the most recent (update) commit first", revisions[0].Message)
+	}
+	if !strings.Contains(revisions[2].Message, "added") {
+		t.Fatalf("revisions[2].Message = %q, want the oldest (add) commit last", revisions[2].Message)
+	}
+
+	limited, err := gitStorage.History(gvk, "test-config", HistoryOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("History with Limit returned error: %v", err)
+	}
+	if len(limited) != 1 || limited[0].Hash != revisions[0].Hash {
+		t.Fatalf("Limit: 1 = %+v, want just the most recent revision %+v", limited, revisions[0])
+	}
+
+	content, err := gitStorage.At(gvk, "test-config", revisions[2].Hash)
+	if err != nil {
+		t.Fatalf("At returned error: %v", err)
+	}
+	if !strings.Contains(string(content), "v1") {
+		t.Fatalf("At(%s) = %q, want content containing %q", revisions[2].Hash, content, "v1")
+	}
+}
+
+// TestGitStorageHistorySinceUntil confirms Since/Until narrow the walk to
+// the commits authored within that window, using the middle revision's own
+// timestamp as the boundary in both directions.
+func TestGitStorageHistorySinceUntil(t *testing.T) {
+	storage, err := NewGitStorage(t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("unable to create GitStorage: %v", err)
+	}
+	gitStorage := storage.(*GitStorage)
+
+	// Git commit timestamps round-trip through the object store at
+	// second granularity, so the gap between commits needs to exceed a
+	// second for Since/Until to see distinct Author.When values.
+	gitStorage.OnAdd(configMapWithData("test-config", "v1"))
+	time.Sleep(1100 * time.Millisecond)
+	gitStorage.OnUpdate(nil, configMapWithData("test-config", "v2"))
+	time.Sleep(1100 * time.Millisecond)
+	gitStorage.OnUpdate(nil, configMapWithData("test-config", "v3"))
+
+	gvk := configMapWithData("test-config", "v3").GroupVersionKind()
+	all, err := gitStorage.History(gvk, "test-config", HistoryOptions{})
+	if err != nil {
+		t.Fatalf("History returned error: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("got %d revisions, want 3", len(all))
+	}
+	middle := all[1].Author.When
+
+	since, err := gitStorage.History(gvk, "test-config", HistoryOptions{Since: middle})
+	if err != nil {
+		t.Fatalf("History with Since returned error: %v", err)
+	}
+	if len(since) != 2 {
+		t.Fatalf("Since(middle) returned %d revisions, want 2", len(since))
+	}
+
+	until, err := gitStorage.History(gvk, "test-config", HistoryOptions{Until: middle})
+	if err != nil {
+		t.Fatalf("History with Until returned error: %v", err)
+	}
+	if len(until) != 2 {
+		t.Fatalf("Until(middle) returned %d revisions, want 2", len(until))
+	}
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	gitssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+
+	"k8s.io/klog"
+)
+
+// mirrorRemoteName is the name of the git remote configured for the offsite
+// mirror push, analogous to the conventional "origin".
+const mirrorRemoteName = "mirror"
+
+const (
+	pushRetries     = 5
+	pushBaseBackoff = time.Second
+	pushMaxBackoff  = time.Minute
+)
+
+// RemoteConfig points GitStorage at an offsite mirror to push commits to.
+// Either SSHKeyPath (for git@host:org/repo.git URLs) or HTTPUsername/HTTPToken
+// (for https:// URLs) should be set, matching whichever scheme URL uses.
+type RemoteConfig struct {
+	URL string
+
+	// SSHKeyPath authenticates pushes over SSH using this private key file.
+	SSHKeyPath string
+
+	// HTTPUsername and HTTPToken authenticate pushes over HTTP(S) as basic
+	// auth, with HTTPToken typically being a personal access token.
+	HTTPUsername string
+	HTTPToken    string
+}
+
+// PushStatus is the outcome of the most recent attempt to push to the mirror.
+type PushStatus struct {
+	Time time.Time
+	Err  error
+}
+
+// setupMirror configures the "mirror" remote and starts the background push
+// loop. It is a no-op when remoteCfg is nil.
+func (s *GitStorage) setupMirror(remoteCfg *RemoteConfig) error {
+	if remoteCfg == nil {
+		return nil
+	}
+	auth, err := buildMirrorAuth(remoteCfg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.repo.Remote(mirrorRemoteName); err == git.ErrRemoteNotFound {
+		if _, err := s.repo.CreateRemote(&config.RemoteConfig{
+			Name: mirrorRemoteName,
+			URLs: []string{remoteCfg.URL},
+		}); err != nil {
+			return fmt.Errorf("unable to configure mirror remote: %v", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("unable to inspect mirror remote: %v", err)
+	}
+
+	s.mirrorAuth = auth
+	// Buffered by one: a pending-but-not-yet-started push already covers
+	// whatever HEAD looks like by the time it runs, so later triggers while
+	// it's queued are redundant and can be dropped.
+	s.pushCh = make(chan struct{}, 1)
+	go s.pushLoop()
+	return nil
+}
+
+// buildMirrorAuth mirrors go-git's own transport/ssh and transport/http
+// packages so both git@host:org/repo.git and https://... mirror URLs work.
+func buildMirrorAuth(remoteCfg *RemoteConfig) (transport.AuthMethod, error) {
+	if strings.HasPrefix(remoteCfg.URL, "http://") || strings.HasPrefix(remoteCfg.URL, "https://") {
+		if remoteCfg.HTTPUsername == "" && remoteCfg.HTTPToken == "" {
+			return nil, nil
+		}
+		return &githttp.BasicAuth{Username: remoteCfg.HTTPUsername, Password: remoteCfg.HTTPToken}, nil
+	}
+	if remoteCfg.SSHKeyPath == "" {
+		return nil, nil
+	}
+	auth, err := gitssh.NewPublicKeysFromFile("git", remoteCfg.SSHKeyPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to load mirror SSH key %q: %v", remoteCfg.SSHKeyPath, err)
+	}
+	return auth, nil
+}
+
+// enqueuePush schedules an asynchronous mirror push, coalescing with any
+// push that is already queued.
+func (s *GitStorage) enqueuePush() {
+	if s.pushCh == nil {
+		return
+	}
+	select {
+	case s.pushCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *GitStorage) pushLoop() {
+	for range s.pushCh {
+		s.pushWithRetry()
+	}
+}
+
+// pushWithRetry pushes to the mirror, retrying with backoff. Each push
+// attempt takes s.Lock(), the same lock OnAdd/OnUpdate/OnDelete hold while
+// committing, so a push is never interleaved with a concurrent commit
+// against the same on-disk repo; the lock is released between attempts so a
+// slow backoff doesn't stall incoming config changes.
+func (s *GitStorage) pushWithRetry() {
+	backoff := pushBaseBackoff
+	var err error
+	for attempt := 0; attempt < pushRetries; attempt++ {
+		s.Lock()
+		err = s.repo.Push(&git.PushOptions{
+			RemoteName: mirrorRemoteName,
+			Auth:       s.mirrorAuth,
+		})
+		s.Unlock()
+		if err == nil || err == git.NoErrAlreadyUpToDate {
+			err = nil
+			break
+		}
+		klog.Warningf("Mirror push attempt %d/%d failed: %v", attempt+1, pushRetries, err)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > pushMaxBackoff {
+			backoff = pushMaxBackoff
+		}
+	}
+
+	s.Lock()
+	s.lastPushStatus = PushStatus{Time: time.Now(), Err: err}
+	s.Unlock()
+
+	if err != nil {
+		klog.Warningf("Unable to push to mirror after %d attempts: %v", pushRetries, err)
+		return
+	}
+	klog.Infof("Pushed config history to mirror %q", mirrorRemoteName)
+}
+
+// LastPushStatus reports the outcome of the most recent mirror push attempt.
+// The zero value means no push has run yet, which is always true when no
+// RemoteConfig was configured.
+func (s *GitStorage) LastPushStatus() PushStatus {
+	s.Lock()
+	defer s.Unlock()
+	return s.lastPushStatus
+}
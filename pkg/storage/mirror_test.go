@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+// TestGitStoragePushesToMirror creates a bare repo as the mirror remote,
+// records a change, and confirms the resulting commit lands on the mirror's
+// master ref without the caller triggering a push itself, polling
+// LastPushStatus until the background push loop reports completion.
+func TestGitStoragePushesToMirror(t *testing.T) {
+	mirrorPath := t.TempDir()
+	if _, err := git.PlainInit(mirrorPath, true); err != nil {
+		t.Fatalf("unable to init bare mirror repo: %v", err)
+	}
+
+	storage, err := NewGitStorage(t.TempDir(), nil, &RemoteConfig{URL: "file://" + mirrorPath})
+	if err != nil {
+		t.Fatalf("unable to create GitStorage: %v", err)
+	}
+	gitStorage := storage.(*GitStorage)
+
+	gitStorage.OnAdd(configMap("test-config"))
+
+	head, err := gitStorage.repo.Head()
+	if err != nil {
+		t.Fatalf("unable to resolve HEAD: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if status := gitStorage.LastPushStatus(); !status.Time.IsZero() {
+			if status.Err != nil {
+				t.Fatalf("mirror push failed: %v", status.Err)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for mirror push to complete")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mirror, err := git.PlainOpen(mirrorPath)
+	if err != nil {
+		t.Fatalf("unable to open mirror repo: %v", err)
+	}
+	mirrorHead, err := mirror.Reference(plumbing.NewBranchReferenceName("master"), true)
+	if err != nil {
+		t.Fatalf("unable to resolve mirror's master ref: %v", err)
+	}
+	if mirrorHead.Hash() != head.Hash() {
+		t.Fatalf("mirror HEAD = %s, want %s", mirrorHead.Hash(), head.Hash())
+	}
+}
+
+// TestGitStorageEnqueuePushCoalesces confirms a push that is already queued
+// absorbs further triggers instead of blocking or queuing a second one, so a
+// burst of OnAdd/OnUpdate calls results in a single pending push.
+func TestGitStorageEnqueuePushCoalesces(t *testing.T) {
+	s := &GitStorage{pushCh: make(chan struct{}, 1)}
+
+	s.enqueuePush()
+	s.enqueuePush()
+	s.enqueuePush()
+
+	if len(s.pushCh) != 1 {
+		t.Fatalf("pushCh has %d queued pushes, want 1", len(s.pushCh))
+	}
+}
+
+// TestBuildMirrorAuth covers the http(s) and SSH/unauthenticated branches of
+// buildMirrorAuth's URL-scheme dispatch.
+func TestBuildMirrorAuth(t *testing.T) {
+	auth, err := buildMirrorAuth(&RemoteConfig{URL: "https://example.com/repo.git", HTTPUsername: "bot", HTTPToken: "token"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	basicAuth, ok := auth.(*githttp.BasicAuth)
+	if !ok {
+		t.Fatalf("got auth of type %T, want *http.BasicAuth", auth)
+	}
+	if basicAuth.Username != "bot" || basicAuth.Password != "token" {
+		t.Fatalf("got BasicAuth{%q, %q}, want {%q, %q}", basicAuth.Username, basicAuth.Password, "bot", "token")
+	}
+
+	auth, err = buildMirrorAuth(&RemoteConfig{URL: "https://example.com/repo.git"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth != nil {
+		t.Fatalf("got auth %v, want nil for an https URL with no credentials", auth)
+	}
+
+	auth, err = buildMirrorAuth(&RemoteConfig{URL: "git@example.com:org/repo.git"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth != nil {
+		t.Fatalf("got auth %v, want nil for an SSH URL with no SSHKeyPath", auth)
+	}
+}
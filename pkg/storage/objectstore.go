@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// errObjectNotFound is returned by objectClient.Get when key does not exist.
+var errObjectNotFound = errors.New("object not found")
+
+// objectClient is the minimal blob-storage operation set ObjectStorage needs.
+// Implementing it against S3 and GCS keeps the manifest/versioning logic in
+// ObjectStorage itself backend-agnostic.
+type objectClient interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// manifestEntry records one historical revision of a tracked resource.
+type manifestEntry struct {
+	Key       string    `json:"key"`
+	Timestamp time.Time `json:"timestamp"`
+	Component string    `json:"component"`
+	Hash      string    `json:"hash"`
+}
+
+// manifest is the small per-resource JSON index of its revisions, keyed
+// alongside the revision objects themselves so Snapshot doesn't need a
+// bucket-listing call on the hot path.
+type manifest struct {
+	Revisions []manifestEntry `json:"revisions"`
+}
+
+// ObjectStorage is a Backend that writes each revision of a tracked resource
+// as an object plus a JSON manifest of its revisions; see NewBackend for why
+// a cluster would choose it over GitStorage.
+type ObjectStorage struct {
+	client objectClient
+	prefix string
+
+	sync.Mutex
+}
+
+// NewObjectStorage builds an ObjectStorage backend that stores every object
+// under prefix in the bucket client talks to.
+func NewObjectStorage(client objectClient, prefix string) *ObjectStorage {
+	return &ObjectStorage{client: client, prefix: prefix}
+}
+
+func (o *ObjectStorage) OnAdd(obj interface{}) {
+	name, content, userInfo, err := decodeUnstructuredObject(obj)
+	if err != nil {
+		klog.Warningf("Unable to decode %q: %v", name, err)
+		return
+	}
+	if err := o.record(name, content, attributedComponent(userInfo)); err != nil {
+		klog.Warningf("Unable to store %q: %v", name, err)
+	}
+}
+
+func (o *ObjectStorage) OnUpdate(_, obj interface{}) {
+	name, content, userInfo, err := decodeUnstructuredObject(obj)
+	if err != nil {
+		klog.Warningf("Unable to decode %q: %v", name, err)
+		return
+	}
+	if err := o.record(name, content, attributedComponent(userInfo)); err != nil {
+		klog.Warningf("Unable to store %q: %v", name, err)
+	}
+}
+
+func (o *ObjectStorage) OnDelete(obj interface{}) {
+	name, _, userInfo, err := decodeUnstructuredObject(obj)
+	if err != nil {
+		klog.Warningf("Unable to decode %q: %v", name, err)
+		return
+	}
+	if err := o.record(name, nil, attributedComponent(userInfo)); err != nil {
+		klog.Warningf("Unable to record deletion of %q: %v", name, err)
+	}
+}
+
+// Snapshot returns the content of the most recent revision recorded for
+// name, as read straight from blob storage.
+func (o *ObjectStorage) Snapshot(name string) ([]byte, error) {
+	o.Lock()
+	defer o.Unlock()
+
+	m, err := o.loadManifest(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Revisions) == 0 {
+		return nil, fmt.Errorf("%q is not tracked", name)
+	}
+	latest := m.Revisions[len(m.Revisions)-1]
+	if latest.Hash == "" {
+		return nil, fmt.Errorf("%q was deleted", name)
+	}
+	return o.client.Get(context.Background(), latest.Key)
+}
+
+// List returns the names of every resource with a manifest under prefix,
+// skipping those whose most recent revision is a deletion tombstone.
+func (o *ObjectStorage) List() ([]string, error) {
+	o.Lock()
+	defer o.Unlock()
+
+	keys, err := o.client.List(context.Background(), o.manifestPrefix())
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		name := strings.TrimSuffix(path.Base(key), ".json")
+		m, err := o.loadManifest(name)
+		if err != nil {
+			return nil, err
+		}
+		if len(m.Revisions) == 0 || m.Revisions[len(m.Revisions)-1].Hash == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// record appends a revision to name's manifest. content is nil for a
+// deletion, which is stored as a tombstone entry with no backing object.
+// If content's hash matches the most recent entry, record is a no-op, the
+// same way GitStorage.commit skips a commit when the worktree is clean —
+// otherwise an informer resync would re-append an unchanged object's
+// revision on every resync period and the manifest would grow unbounded.
+func (o *ObjectStorage) record(name string, content []byte, component string) error {
+	o.Lock()
+	defer o.Unlock()
+
+	ctx := context.Background()
+	var hash string
+	if content != nil {
+		hash = contentHash(content)
+	}
+
+	m, err := o.loadManifest(name)
+	if err != nil {
+		return err
+	}
+	if len(m.Revisions) > 0 && m.Revisions[len(m.Revisions)-1].Hash == hash {
+		return nil
+	}
+
+	entry := manifestEntry{Timestamp: time.Now(), Component: component}
+	if content != nil {
+		entry.Hash = hash
+		entry.Key = o.revisionKey(name, hash)
+		if err := o.client.Put(ctx, entry.Key, content); err != nil {
+			return err
+		}
+	}
+
+	m.Revisions = append(m.Revisions, entry)
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return o.client.Put(ctx, o.manifestKey(name), data)
+}
+
+func (o *ObjectStorage) loadManifest(name string) (manifest, error) {
+	data, err := o.client.Get(context.Background(), o.manifestKey(name))
+	if err == errObjectNotFound {
+		return manifest{}, nil
+	}
+	if err != nil {
+		return manifest{}, err
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return manifest{}, err
+	}
+	return m, nil
+}
+
+func (o *ObjectStorage) manifestPrefix() string {
+	return path.Join(o.prefix, "manifests") + "/"
+}
+
+func (o *ObjectStorage) manifestKey(name string) string {
+	return path.Join(o.prefix, "manifests", name+".json")
+}
+
+func (o *ObjectStorage) revisionKey(name, hash string) string {
+	return path.Join(o.prefix, "revisions", name, hash)
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
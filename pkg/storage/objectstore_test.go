@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeObjectClient is an in-memory objectClient, so ObjectStorage's manifest
+// bookkeeping can be tested without a real S3/GCS bucket.
+type fakeObjectClient struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newFakeObjectClient() *fakeObjectClient {
+	return &fakeObjectClient{objects: make(map[string][]byte)}
+}
+
+func (f *fakeObjectClient) Put(_ context.Context, key string, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (f *fakeObjectClient) Get(_ context.Context, key string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[key]
+	if !ok {
+		return nil, errObjectNotFound
+	}
+	return data, nil
+}
+
+func (f *fakeObjectClient) List(_ context.Context, prefix string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// TestObjectStorageRecordsRevisionsAndSnapshots confirms OnAdd/OnUpdate each
+// append a manifest revision for genuinely changed content and that
+// Snapshot/List read them back.
+func TestObjectStorageRecordsRevisionsAndSnapshots(t *testing.T) {
+	o := NewObjectStorage(newFakeObjectClient(), "history")
+
+	o.OnAdd(configMapWithData("test-config", "v1"))
+	o.OnUpdate(nil, configMapWithData("test-config", "v2"))
+
+	m, err := o.loadManifest("configmap.v1..yaml")
+	if err != nil {
+		t.Fatalf("loadManifest returned error: %v", err)
+	}
+	if len(m.Revisions) != 2 {
+		t.Fatalf("got %d revisions, want 2", len(m.Revisions))
+	}
+
+	content, err := o.Snapshot("configmap.v1..yaml")
+	if err != nil {
+		t.Fatalf("Snapshot returned error: %v", err)
+	}
+	if !strings.Contains(string(content), "v2") {
+		t.Fatalf("Snapshot = %q, want content containing %q", content, "v2")
+	}
+
+	names, err := o.List()
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(names) != 1 || names[0] != "configmap.v1..yaml" {
+		t.Fatalf("List = %v, want [configmap.v1..yaml]", names)
+	}
+}
+
+// TestObjectStorageSkipsUnchangedResync confirms record is a no-op when the
+// incoming content's hash matches the most recent revision, the way a
+// resync-triggered OnUpdate on an unmodified resource should not grow the
+// manifest.
+func TestObjectStorageSkipsUnchangedResync(t *testing.T) {
+	o := NewObjectStorage(newFakeObjectClient(), "history")
+
+	o.OnAdd(configMapWithData("test-config", "v1"))
+	o.OnUpdate(nil, configMapWithData("test-config", "v1"))
+	o.OnUpdate(nil, configMapWithData("test-config", "v1"))
+
+	m, err := o.loadManifest("configmap.v1..yaml")
+	if err != nil {
+		t.Fatalf("loadManifest returned error: %v", err)
+	}
+	if len(m.Revisions) != 1 {
+		t.Fatalf("got %d revisions after repeated resyncs of unchanged content, want 1", len(m.Revisions))
+	}
+
+	o.OnUpdate(nil, configMapWithData("test-config", "v2"))
+	m, err = o.loadManifest("configmap.v1..yaml")
+	if err != nil {
+		t.Fatalf("loadManifest returned error: %v", err)
+	}
+	if len(m.Revisions) != 2 {
+		t.Fatalf("got %d revisions after a genuine change, want 2", len(m.Revisions))
+	}
+}
+
+// TestObjectStorageDeleteTombstones confirms OnDelete appends a tombstone
+// entry with no backing object, and that Snapshot reports the resource as
+// deleted rather than returning stale content.
+func TestObjectStorageDeleteTombstones(t *testing.T) {
+	o := NewObjectStorage(newFakeObjectClient(), "history")
+
+	o.OnAdd(configMapWithData("test-config", "v1"))
+	o.OnDelete(configMapWithData("test-config", "v1"))
+
+	m, err := o.loadManifest("configmap.v1..yaml")
+	if err != nil {
+		t.Fatalf("loadManifest returned error: %v", err)
+	}
+	if len(m.Revisions) != 2 {
+		t.Fatalf("got %d revisions, want 2", len(m.Revisions))
+	}
+	if last := m.Revisions[len(m.Revisions)-1]; last.Hash != "" || last.Key != "" {
+		t.Fatalf("tombstone entry = %+v, want empty Hash and Key", last)
+	}
+
+	if _, err := o.Snapshot("configmap.v1..yaml"); err == nil {
+		t.Fatal("Snapshot succeeded for a deleted resource, want an error")
+	}
+}
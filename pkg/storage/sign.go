@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+// Environment variables consulted by NewPGPSignerFromEnv. The passphrase is
+// read from the environment rather than taken as a flag so it never shows up
+// in a process listing or a CR spec.
+const (
+	signingKeyPathEnvVar    = "CONFIG_HISTORY_SIGNING_KEY_PATH"
+	signingPassphraseEnvVar = "CONFIG_HISTORY_SIGNING_KEY_PASSPHRASE"
+)
+
+// SignerProvider hands back the PGP entity used to sign commits. Implementing
+// this against Vault or a KMS instead of a file on disk is the extension
+// point this interface exists for.
+type SignerProvider interface {
+	Entity() (*openpgp.Entity, error)
+}
+
+// fileSigner loads an armored private key once and keeps the decrypted
+// entity in memory for the lifetime of the process.
+type fileSigner struct {
+	entity *openpgp.Entity
+}
+
+// NewPGPSigner reads an armored PGP private key from keyPath and, if it is
+// passphrase-protected, decrypts it. The returned SignerProvider is safe for
+// concurrent use.
+func NewPGPSigner(keyPath string, passphrase []byte) (SignerProvider, error) {
+	f, err := os.Open(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open signing key %q: %v", keyPath, err)
+	}
+	defer f.Close()
+
+	keyring, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read signing key %q: %v", keyPath, err)
+	}
+	if len(keyring) == 0 {
+		return nil, fmt.Errorf("signing key %q contains no entities", keyPath)
+	}
+	entity := keyring[0]
+
+	if entity.PrivateKey != nil && entity.PrivateKey.Encrypted {
+		if err := entity.PrivateKey.Decrypt(passphrase); err != nil {
+			return nil, fmt.Errorf("unable to decrypt signing key %q: %v", keyPath, err)
+		}
+	}
+	for _, subkey := range entity.Subkeys {
+		if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+			if err := subkey.PrivateKey.Decrypt(passphrase); err != nil {
+				return nil, fmt.Errorf("unable to decrypt signing subkey of %q: %v", keyPath, err)
+			}
+		}
+	}
+
+	return &fileSigner{entity: entity}, nil
+}
+
+// NewPGPSignerFromEnv builds a SignerProvider from CONFIG_HISTORY_SIGNING_KEY_PATH
+// and CONFIG_HISTORY_SIGNING_KEY_PASSPHRASE. It returns a nil SignerProvider
+// (and a nil error) when no key path is configured, so commit signing stays
+// opt-in and NewGitStorage's behavior is unchanged by default.
+func NewPGPSignerFromEnv() (SignerProvider, error) {
+	keyPath := os.Getenv(signingKeyPathEnvVar)
+	if keyPath == "" {
+		return nil, nil
+	}
+	return NewPGPSigner(keyPath, []byte(os.Getenv(signingPassphraseEnvVar)))
+}
+
+func (s *fileSigner) Entity() (*openpgp.Entity, error) {
+	return s.entity, nil
+}
+
+// signerIdentity returns the name/email pair from the entity's primary
+// UserId, used as the commit Author so `git log --show-signature` verifies
+// against the same identity that produced the signature. entity.Identities
+// is a map, so iteration order is randomized by Go itself; identity keys are
+// sorted first to make the result deterministic across calls.
+func signerIdentity(entity *openpgp.Entity) (name, email string) {
+	keys := make([]string, 0, len(entity.Identities))
+	for k := range entity.Identities {
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return "", ""
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		identity := entity.Identities[k]
+		if identity.SelfSignature != nil && identity.SelfSignature.IsPrimaryId != nil && *identity.SelfSignature.IsPrimaryId {
+			return identity.UserId.Name, identity.UserId.Email
+		}
+	}
+	// No UserId was explicitly marked primary; fall back to a fixed
+	// (sorted-key-order) choice rather than an arbitrary one.
+	first := entity.Identities[keys[0]]
+	return first.UserId.Name, first.UserId.Email
+}
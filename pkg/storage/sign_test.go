@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// entitySigner is a SignerProvider backed directly by an in-memory
+// *openpgp.Entity, so tests don't need to round-trip a key through disk.
+type entitySigner struct {
+	entity *openpgp.Entity
+}
+
+func (s *entitySigner) Entity() (*openpgp.Entity, error) {
+	return s.entity, nil
+}
+
+// armoredPublicKey renders entity's public key as an armored keyring, the
+// form Commit.Verify expects.
+func armoredPublicKey(t *testing.T, entity *openpgp.Entity) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("unable to open armor writer: %v", err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatalf("unable to serialize public key: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unable to close armor writer: %v", err)
+	}
+	return buf.String()
+}
+
+// TestGitStorageSignsCommits creates a repo, records a change through OnAdd,
+// and verifies the resulting commit's signature against an in-memory keyring.
+func TestGitStorageSignsCommits(t *testing.T) {
+	entity, err := openpgp.NewEntity("Config History Operator", "", "operator@openshift.io", nil)
+	if err != nil {
+		t.Fatalf("unable to generate PGP entity: %v", err)
+	}
+
+	storage, err := NewGitStorage(t.TempDir(), &entitySigner{entity: entity}, nil)
+	if err != nil {
+		t.Fatalf("unable to create GitStorage: %v", err)
+	}
+	gitStorage := storage.(*GitStorage)
+
+	gitStorage.OnAdd(configMap("test-config"))
+
+	repo := gitStorage.repo
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("unable to resolve HEAD: %v", err)
+	}
+	commit, err := object.GetCommit(repo.Storer, head.Hash())
+	if err != nil {
+		t.Fatalf("unable to load HEAD commit: %v", err)
+	}
+	if commit.PGPSignature == "" {
+		t.Fatal("HEAD commit carries no PGP signature")
+	}
+
+	signer, err := commit.Verify(armoredPublicKey(t, entity))
+	if err != nil {
+		t.Fatalf("signature did not verify: %v", err)
+	}
+	if signer.PrimaryKey.KeyId != entity.PrimaryKey.KeyId {
+		t.Fatalf("commit was signed by key %x, want %x", signer.PrimaryKey.KeyId, entity.PrimaryKey.KeyId)
+	}
+
+	// A keyring that doesn't contain the signer must not verify.
+	other, err := openpgp.NewEntity("Someone Else", "", "someone@openshift.io", nil)
+	if err != nil {
+		t.Fatalf("unable to generate second PGP entity: %v", err)
+	}
+	if _, err := commit.Verify(armoredPublicKey(t, other)); err == nil {
+		t.Fatal("signature unexpectedly verified against an unrelated keyring")
+	}
+}
+
+// TestGitStorageUnsignedWhenNoSigner confirms commits stay unsigned when
+// GitStorage is constructed without a SignerProvider, matching NewGitStorage's
+// documented default.
+func TestGitStorageUnsignedWhenNoSigner(t *testing.T) {
+	storage, err := NewGitStorage(t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("unable to create GitStorage: %v", err)
+	}
+	gitStorage := storage.(*GitStorage)
+
+	gitStorage.OnAdd(configMap("test-config"))
+
+	repo := gitStorage.repo
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("unable to resolve HEAD: %v", err)
+	}
+	commit, err := object.GetCommit(repo.Storer, head.Hash())
+	if err != nil {
+		t.Fatalf("unable to load HEAD commit: %v", err)
+	}
+	if commit.PGPSignature != "" {
+		t.Fatal("HEAD commit carries a PGP signature despite no signer being configured")
+	}
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/damemi/config-history-operator/pkg/admission"
+)
+
+// unstructuredResource builds a minimal unstructured object of kind, named
+// name, with data["key"] set to value when value is non-empty and the given
+// annotations on metadata. It's the shared base every test helper in this
+// package uses to build a ConfigMap-shaped (or ConfigMap-like) object, so the
+// object-literal boilerplate lives in one place instead of being repasted per
+// test file.
+func unstructuredResource(kind, name, value string, annotations map[string]string) *unstructured.Unstructured {
+	metadata := map[string]interface{}{"name": name}
+	if len(annotations) > 0 {
+		ann := make(map[string]interface{}, len(annotations))
+		for k, v := range annotations {
+			ann[k] = v
+		}
+		metadata["annotations"] = ann
+	}
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       kind,
+			"metadata":   metadata,
+		},
+	}
+	if value != "" {
+		obj.Object["data"] = map[string]interface{}{"key": value}
+	}
+	obj.SetGroupVersionKind(obj.GroupVersionKind())
+	return obj
+}
+
+// configMap builds a minimal ConfigMap named name.
+func configMap(name string) *unstructured.Unstructured {
+	return unstructuredResource("ConfigMap", name, "value", nil)
+}
+
+// configMapWithData is like configMap but lets each revision carry distinct
+// content, so successive commits produce distinct blob hashes for History to
+// walk.
+func configMapWithData(name, value string) *unstructured.Unstructured {
+	return unstructuredResource("ConfigMap", name, value, nil)
+}
+
+// resourceOfKind builds a minimal unstructured object of the given kind,
+// using the kind itself as a stand-in name so tests can tell instances
+// apart. resourceFilename keys purely off GVK (the operator tracks
+// cluster-scoped singletons), so distinguishing resources in a diff test
+// requires distinct kinds rather than distinct names.
+func resourceOfKind(kind, value string) *unstructured.Unstructured {
+	return unstructuredResource(kind, kind, value, nil)
+}
+
+// configMapWithAnnotation builds a ConfigMap carrying the given
+// admission.AnnotationKey annotation verbatim, so tests can simulate a
+// crafted UserInfo arriving the same way the mutating webhook's stamp does.
+func configMapWithAnnotation(name, annotation string) *unstructured.Unstructured {
+	return unstructuredResource("ConfigMap", name, "", map[string]string{admission.AnnotationKey: annotation})
+}